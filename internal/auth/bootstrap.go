@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/inodinwetrust10/rewardPoints/internal/db"
+)
+
+// ErrAlreadyBootstrapped is returned by Bootstrap when an admin token
+// already exists, so operators don't accidentally mint a second one by
+// re-running the bootstrap subcommand.
+var ErrAlreadyBootstrapped = errors.New("an admin token already exists")
+
+// Bootstrap creates the first admin token, returning its raw (unhashed)
+// value. It is meant to be run once, from a CLI subcommand, before the
+// server enforces auth on any other route.
+func Bootstrap(ctx context.Context, s *db.Store) (string, error) {
+	tokens, err := s.ListAccessTokens(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list access tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if t.Type == TokenAdmin {
+			return "", ErrAlreadyBootstrapped
+		}
+	}
+
+	raw, hash, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.CreateAccessToken(ctx, TokenAdmin, 0, hash); err != nil {
+		return "", fmt.Errorf("create admin token: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/inodinwetrust10/rewardPoints/internal/models"
+)
+
+type contextKey int
+
+const tokenContextKey contextKey = 0
+
+// FromContext returns the AccessToken that authenticated the current
+// request, as set by Middleware.
+func FromContext(ctx context.Context) (*models.AccessToken, bool) {
+	t, ok := ctx.Value(tokenContextKey).(*models.AccessToken)
+	return t, ok
+}
+
+func withToken(ctx context.Context, t *models.AccessToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey, t)
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/inodinwetrust10/rewardPoints/internal/db"
+	"github.com/inodinwetrust10/rewardPoints/internal/models"
+)
+
+// Authenticator validates bearer tokens against the access_tokens table.
+type Authenticator struct {
+	s *db.Store
+}
+
+// New builds an Authenticator backed by s.
+func New(s *db.Store) *Authenticator {
+	return &Authenticator{s: s}
+}
+
+// Middleware enforces "Authorization: Bearer <token>" on every request,
+// attaching the resolved AccessToken to the request context. Mount it on
+// the /api/v1 subrouter only; it is not meant to cover /health.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := bearerToken(r)
+		if !ok {
+			jsErr(w, http.StatusUnauthorized, "missing or malformed Authorization header")
+			return
+		}
+
+		t, err := a.s.GetAccessTokenByHash(r.Context(), HashToken(raw))
+		if err != nil {
+			if errors.Is(err, db.ErrTokenNotFound) {
+				jsErr(w, http.StatusUnauthorized, "invalid access token")
+				return
+			}
+			log.Printf("GetAccessTokenByHash: %v", err)
+			jsErr(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withToken(r.Context(), t)))
+	})
+}
+
+// RequireScopes returns middleware that rejects requests whose token type
+// is not one of allowed. Mount it on individual routes that need tighter
+// access than the default of "any authenticated token".
+func RequireScopes(allowed ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, ok := FromContext(r.Context())
+			if !ok {
+				jsErr(w, http.StatusUnauthorized, "missing or malformed Authorization header")
+				return
+			}
+			for _, scope := range allowed {
+				if t.Type == scope {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			jsErr(w, http.StatusForbidden, "token is not permitted to call this operation")
+		})
+	}
+}
+
+// bearerToken extracts the token from a "Bearer <token>" Authorization
+// header.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return "", false
+	}
+	tok := strings.TrimSpace(strings.TrimPrefix(h, prefix))
+	if tok == "" {
+		return "", false
+	}
+	return tok, true
+}
+
+func jsErr(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(models.ErrResp{Error: msg}); err != nil {
+		log.Printf("json encode: %v", err)
+	}
+}
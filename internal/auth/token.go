@@ -0,0 +1,42 @@
+// Package auth implements bearer-token authentication and scope-based
+// authorization for /api/v1, modeled on Bytom's accessTokens: tokens are a
+// random secret whose SHA-256 hash is the only thing persisted, typed as
+// "admin" (full access) or "client" (scoped to a single user's Spend
+// calls).
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// TokenAdmin and TokenClient are the only valid AccessToken.Type values.
+const (
+	TokenAdmin  = "admin"
+	TokenClient = "client"
+)
+
+// rawTokenBytes is the amount of entropy in a generated token, before
+// hex-encoding.
+const rawTokenBytes = 32
+
+// GenerateToken returns a new random raw token and its SHA-256 hash. The
+// raw value is shown to the caller exactly once, at creation time; only
+// hash is ever persisted.
+func GenerateToken() (raw, hash string, err error) {
+	buf := make([]byte, rawTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate token: %w", err)
+	}
+	raw = hex.EncodeToString(buf)
+	return raw, HashToken(raw), nil
+}
+
+// HashToken returns the SHA-256 hash of a raw token, hex-encoded, for
+// lookup against the stored token_hash column.
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/inodinwetrust10/rewardPoints/internal/db"
+)
+
+// sentinelErrors maps the error names used in vector files to the
+// db package sentinels they must match via errors.Is.
+var sentinelErrors = map[string]error{
+	"ErrInsufficientBalance": db.ErrInsufficientBalance,
+	"ErrWalletNotFound":      db.ErrWalletNotFound,
+	"ErrInvalidAmount":       db.ErrInvalidAmount,
+	"ErrMissingIdempotency":  db.ErrMissingIdempotency,
+}
+
+// Run seeds pool with v's wallets, replays v's transfers against store, and
+// diffs the resulting balances and ledger against v's expectations. It
+// returns the first mismatch found, or nil if the vector passed.
+func Run(ctx context.Context, pool *pgxpool.Pool, store *db.Store, v Vector) error {
+	if err := seed(ctx, pool, v.Seed); err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+
+	for i, tr := range v.Transfers {
+		_, err := store.ExecuteTransfer(ctx, tr.FromWalletID, tr.ToWalletID, tr.Amount, tr.IdempotencyKey, tr.Description, tr.TxType)
+		if tr.ExpectErr == "" {
+			if err != nil {
+				return fmt.Errorf("transfer %d: unexpected error: %w", i, err)
+			}
+			continue
+		}
+		want, ok := sentinelErrors[tr.ExpectErr]
+		if !ok {
+			return fmt.Errorf("transfer %d: unknown expect_err %q", i, tr.ExpectErr)
+		}
+		if err == nil {
+			return fmt.Errorf("transfer %d: expected %s, got success", i, tr.ExpectErr)
+		}
+		if !errors.Is(err, want) {
+			return fmt.Errorf("transfer %d: expected %s, got %v", i, tr.ExpectErr, err)
+		}
+	}
+
+	for wid, want := range v.ExpectedBalances {
+		bal, err := store.GetWalletBalance(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("balance wallet %d: %w", wid, err)
+		}
+		if bal.Balance != want {
+			return fmt.Errorf("balance wallet %d: want %d, got %d", wid, want, bal.Balance)
+		}
+	}
+
+	if v.ExpectedLedger != nil {
+		if err := diffLedger(ctx, store, v.ExpectedLedger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffLedger compares want against the ledger entries actually recorded for
+// every wallet it references. want must list entries in ascending
+// wallet-ID order (ties broken by replay order within a wallet); vector
+// files should be written accordingly.
+func diffLedger(ctx context.Context, store *db.Store, want []ExpectedEntry) error {
+	seen := map[int]bool{}
+	for _, e := range want {
+		seen[e.WalletID] = true
+	}
+
+	ids := make([]int, 0, len(seen))
+	for wid := range seen {
+		ids = append(ids, wid)
+	}
+	sort.Ints(ids)
+
+	var got []ExpectedEntry
+	for _, wid := range ids {
+		entries, err := store.GetLedgerEntries(ctx, wid)
+		if err != nil {
+			return fmt.Errorf("ledger wallet %d: %w", wid, err)
+		}
+		// GetLedgerEntries returns newest-first; replay order is oldest-first.
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			got = append(got, ExpectedEntry{
+				TxGroupID: e.TxGroupID,
+				WalletID:  e.WalletID,
+				EntryType: e.EntryType,
+				Amount:    e.Amount,
+				TxType:    e.TxType,
+			})
+		}
+	}
+
+	if len(got) != len(want) {
+		return fmt.Errorf("ledger: want %d entries, got %d", len(want), len(got))
+	}
+	for i := range want {
+		w := want[i]
+		g := got[i]
+		if w.TxGroupID == "" {
+			w.TxGroupID = g.TxGroupID // tx_group_id is generated; only compare when pinned
+		}
+		if w != g {
+			return fmt.Errorf("ledger entry %d: want %+v, got %+v", i, w, g)
+		}
+	}
+	return nil
+}
+
+func seed(ctx context.Context, pool *pgxpool.Pool, wallets []SeedWallet) error {
+	for _, w := range wallets {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO asset_types (id, code, name) VALUES ($1, $2, $2)
+			ON CONFLICT (id) DO NOTHING`, w.AssetTypeID, w.AssetCode); err != nil {
+			return fmt.Errorf("seed asset_type %d: %w", w.AssetTypeID, err)
+		}
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO wallets (id, owner_id, asset_type_id, balance)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (id) DO UPDATE SET balance = EXCLUDED.balance`,
+			w.ID, w.OwnerID, w.AssetTypeID, w.Balance); err != nil {
+			return fmt.Errorf("seed wallet %d: %w", w.ID, err)
+		}
+	}
+	return nil
+}
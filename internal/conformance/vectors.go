@@ -0,0 +1,88 @@
+// Package conformance drives internal/db.Store against a corpus of
+// versioned test vectors describing wallet seed state, an ordered list of
+// transfers, and the expected resulting ledger and balances. It gives a
+// shareable way to verify accounting invariants (double-entry symmetry,
+// idempotency replay, insufficient-balance rejection) across schema and
+// store refactors, independent of any one handler or transport.
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SeedWallet describes a wallet's starting state for a vector.
+type SeedWallet struct {
+	ID          int    `json:"id"`
+	OwnerID     int    `json:"owner_id"`
+	AssetCode   string `json:"asset_code"`
+	AssetTypeID int    `json:"asset_type_id"`
+	Balance     int64  `json:"balance"`
+}
+
+// Transfer describes one ExecuteTransfer call to replay.
+type Transfer struct {
+	FromWalletID   int    `json:"from_wallet_id"`
+	ToWalletID     int    `json:"to_wallet_id"`
+	Amount         int64  `json:"amount"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Description    string `json:"description"`
+	TxType         string `json:"tx_type"`
+	// ExpectErr is the sentinel error name expected from db.Store (e.g.
+	// "ErrInsufficientBalance"), or empty if the transfer must succeed.
+	ExpectErr string `json:"expect_err,omitempty"`
+}
+
+// ExpectedEntry is a single ledger row a vector expects to exist after
+// replay, compared on the fields below only; TxGroupID is compared only
+// when the vector sets it.
+type ExpectedEntry struct {
+	TxGroupID string `json:"tx_group_id,omitempty"`
+	WalletID  int    `json:"wallet_id"`
+	EntryType string `json:"entry_type"`
+	Amount    int64  `json:"amount"`
+	TxType    string `json:"tx_type"`
+}
+
+// Vector is one conformance test case.
+type Vector struct {
+	Name             string        `json:"name"`
+	Seed             []SeedWallet  `json:"seed"`
+	Transfers        []Transfer    `json:"transfers"`
+	ExpectedBalances map[int]int64 `json:"expected_balances"`
+	// ExpectedLedger must list entries in ascending wallet-ID order (ties
+	// broken by replay order within a wallet); diffLedger compares
+	// positionally against entries collected wallet-by-wallet in that
+	// same order.
+	ExpectedLedger []ExpectedEntry `json:"expected_ledger"`
+}
+
+// Load reads every *.json vector file in dir, sorted by filename for a
+// stable, reproducible run order.
+func Load(dir string) ([]Vector, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("glob vectors: %w", err)
+	}
+	sort.Strings(paths)
+
+	vectors := make([]Vector, 0, len(paths))
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read vector %s: %w", p, err)
+		}
+		var v Vector
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("parse vector %s: %w", p, err)
+		}
+		if v.Name == "" {
+			v.Name = filepath.Base(p)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/inodinwetrust10/rewardPoints/internal/db/migrations"
+	"github.com/inodinwetrust10/rewardPoints/internal/events"
 	"github.com/inodinwetrust10/rewardPoints/internal/models"
 )
 
@@ -18,16 +22,52 @@ var (
 	ErrWalletNotFound      = errors.New("wallet not found")
 	ErrInvalidAmount       = errors.New("amount must be positive")
 	ErrMissingIdempotency  = errors.New("idempotency_key is required")
+
+	// ErrInvalidPostings is returned by ExecutePostings when fewer than
+	// two postings are given; a posting set needs at least one debit and
+	// one credit to balance.
+	ErrInvalidPostings = errors.New("at least two postings are required")
+	// ErrUnbalancedPostings is returned by ExecutePostings when the given
+	// postings do not sum to zero.
+	ErrUnbalancedPostings = errors.New("postings must sum to zero")
+
+	// ErrSchemaVersionMismatch is returned by NewStore when the database's
+	// applied schema_version does not match the version this build of the
+	// code expects. Run the binary with --migrate to bring the database
+	// up to date before starting the server.
+	ErrSchemaVersionMismatch = errors.New("schema version mismatch")
+
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is not pending")
+
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+
+	ErrTokenNotFound = errors.New("access token not found")
 )
 
 type Store struct {
 	Pool *pgxpool.Pool
 }
 
-func NewStore(p *pgxpool.Pool) *Store {
-	return &Store{Pool: p}
+// NewStore connects Store to pool after confirming the database's applied
+// schema_version matches migrations.CurrentVersion(). It refuses to start
+// on a mismatch rather than risk running against a schema it doesn't
+// understand.
+func NewStore(ctx context.Context, p *pgxpool.Pool) (*Store, error) {
+	applied, err := migrations.AppliedVersion(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+	if want := migrations.CurrentVersion(); applied != want {
+		return nil, fmt.Errorf("%w: code expects version %d, database has %d (run with --migrate)",
+			ErrSchemaVersionMismatch, want, applied)
+	}
+	return &Store{Pool: p}, nil
 }
 
+// ExecuteTransfer moves amt from fromID to toID as a balanced two-entry
+// posting. It is a thin convenience wrapper around ExecutePostings for the
+// common DEBIT/CREDIT pair.
 func (s *Store) ExecuteTransfer(
 	ctx context.Context,
 	fromID, toID int,
@@ -36,10 +76,45 @@ func (s *Store) ExecuteTransfer(
 	desc string,
 	txType string,
 ) (*models.TxResp, error) {
-
 	if amt <= 0 {
 		return nil, ErrInvalidAmount
 	}
+	return s.ExecutePostings(ctx, []models.Posting{
+		{WalletID: fromID, Amount: -amt},
+		{WalletID: toID, Amount: amt},
+	}, iKey, desc, txType)
+}
+
+// ExecutePostings atomically applies an arbitrary balanced set of debits
+// (negative Amount) and credits (positive Amount) under a single
+// tx_group_id, generalizing the 2-entry ExecuteTransfer to N legs so
+// callers can express fees, splits, and bonus stacking in one atomic,
+// idempotent operation. Wallet IDs are locked in sorted order to preserve
+// the existing deadlock-avoidance discipline. Insufficient-balance checks
+// run against each wallet's final available balance (balance minus funds
+// held by pending reservations) after all of its postings are applied, not
+// after each individual posting, so a wallet may legally go negative in an
+// intermediate step as long as its net effect doesn't.
+func (s *Store) ExecutePostings(
+	ctx context.Context,
+	postings []models.Posting,
+	iKey string,
+	desc string,
+	txType string,
+) (*models.TxResp, error) {
+	if len(postings) < 2 {
+		return nil, ErrInvalidPostings
+	}
+	var sum int64
+	for _, p := range postings {
+		if p.Amount == 0 {
+			return nil, ErrInvalidAmount
+		}
+		sum += p.Amount
+	}
+	if sum != 0 {
+		return nil, ErrUnbalancedPostings
+	}
 	if iKey == "" {
 		return nil, ErrMissingIdempotency
 	}
@@ -67,36 +142,346 @@ func (s *Store) ExecuteTransfer(
 		}
 	}()
 
-	fst, snd := fromID, toID
-	if fst > snd {
-		fst, snd = snd, fst
+	ids := sortedUniqueWalletIDs(postings)
+	balances := make(map[int]int64, len(ids))
+	reserved := make(map[int]int64, len(ids))
+	for _, id := range ids {
+		var bal, res int64
+		err := tx.QueryRow(ctx, "SELECT balance, reserved_balance FROM wallets WHERE id = $1 FOR UPDATE", id).Scan(&bal, &res)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrWalletNotFound
+			}
+			return nil, fmt.Errorf("lock wallet %d: %w", id, err)
+		}
+		balances[id] = bal
+		reserved[id] = res
+	}
+
+	net := make(map[int]int64, len(ids))
+	for _, p := range postings {
+		balances[p.WalletID] += p.Amount
+		net[p.WalletID] += p.Amount
+	}
+	for _, id := range ids {
+		if balances[id] < 0 || (net[id] < 0 && balances[id] < reserved[id]) {
+			return nil, ErrInsufficientBalance
+		}
+	}
+
+	gid := uuid.New().String()
+
+	entries := make([]models.LedgerEntry, 0, len(postings))
+	for _, p := range postings {
+		entryType, amt := "CREDIT", p.Amount
+		if p.Amount < 0 {
+			entryType, amt = "DEBIT", -p.Amount
+		}
+
+		var e models.LedgerEntry
+		err = tx.QueryRow(ctx, `
+			INSERT INTO ledger_entries (tx_group_id, idempotency_key, wallet_id, entry_type, amount, tx_type, description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, tx_group_id, idempotency_key, wallet_id, entry_type, amount, tx_type, description, created_at`,
+			gid, iKey, p.WalletID, entryType, amt, txType, desc,
+		).Scan(&e.ID, &e.TxGroupID, &e.IdempotencyKey, &e.WalletID, &e.EntryType, &e.Amount, &e.TxType, &e.Desc, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("insert %s for wallet %d: %w", entryType, p.WalletID, err)
+		}
+		entries = append(entries, e)
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec(ctx, "UPDATE wallets SET balance = $1 WHERE id = $2", balances[id], id); err != nil {
+			return nil, fmt.Errorf("update wallet %d: %w", id, err)
+		}
+	}
+
+	if err := events.EnqueueTx(ctx, tx, events.TransferCommitted{
+		TxGroupID:   gid,
+		TxType:      txType,
+		Entries:     entries,
+		CommittedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("enqueue outbox event: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &models.TxResp{
+		TxGroupID:      gid,
+		IdempotencyKey: iKey,
+		Status:         "created",
+		Entries:        entries,
+	}, nil
+}
+
+// sortedUniqueWalletIDs returns the distinct wallet IDs touched by
+// postings in ascending order, so callers can lock them in a fixed order
+// regardless of how many postings reference the same wallet.
+func sortedUniqueWalletIDs(postings []models.Posting) []int {
+	seen := make(map[int]bool, len(postings))
+	ids := make([]int, 0, len(postings))
+	for _, p := range postings {
+		if !seen[p.WalletID] {
+			seen[p.WalletID] = true
+			ids = append(ids, p.WalletID)
+		}
 	}
+	sort.Ints(ids)
+	return ids
+}
 
-	var fstBal, sndBal int64
-	err = tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE id = $1 FOR UPDATE", fst).Scan(&fstBal)
+func (s *Store) GetWalletBalance(ctx context.Context, wid int) (*models.BalResp, error) {
+	var r models.BalResp
+	var reserved int64
+	err := s.Pool.QueryRow(ctx, `
+		SELECT w.id, w.owner_id, a.code, w.balance, w.reserved_balance
+		FROM wallets w JOIN asset_types a ON a.id = w.asset_type_id
+		WHERE w.id = $1`, wid,
+	).Scan(&r.WalletID, &r.OwnerID, &r.AssetCode, &r.Balance, &reserved)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrWalletNotFound
 		}
-		return nil, fmt.Errorf("lock wallet %d: %w", fst, err)
+		return nil, err
 	}
+	r.AvailableBalance = r.Balance - reserved
+	return &r, nil
+}
 
-	err = tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE id = $1 FOR UPDATE", snd).Scan(&sndBal)
+func (s *Store) GetLedgerEntries(ctx context.Context, wid int) ([]models.LedgerEntry, error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT id, tx_group_id, idempotency_key, wallet_id, entry_type, amount, tx_type, description, created_at
+		FROM ledger_entries WHERE wallet_id = $1 ORDER BY created_at DESC`, wid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LedgerEntry
+	for rows.Next() {
+		var e models.LedgerEntry
+		if err := rows.Scan(&e.ID, &e.TxGroupID, &e.IdempotencyKey, &e.WalletID, &e.EntryType, &e.Amount, &e.TxType, &e.Desc, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *Store) GetWalletByOwnerAndAsset(ctx context.Context, ownerID int, asset string) (*models.Wallet, error) {
+	var w models.Wallet
+	err := s.Pool.QueryRow(ctx, `
+		SELECT w.id, w.owner_id, w.asset_type_id, w.balance, w.created_at
+		FROM wallets w JOIN asset_types a ON a.id = w.asset_type_id
+		WHERE w.owner_id = $1 AND a.code = $2`, ownerID, asset,
+	).Scan(&w.ID, &w.OwnerID, &w.AssetTypeID, &w.Balance, &w.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (s *Store) GetTreasuryWallet(ctx context.Context, asset string) (*models.Wallet, error) {
+	return s.GetWalletByOwnerAndAsset(ctx, 1, asset)
+}
+
+func (s *Store) GetWalletByID(ctx context.Context, wid int) (*models.Wallet, error) {
+	var w models.Wallet
+	err := s.Pool.QueryRow(ctx, `
+		SELECT id, owner_id, asset_type_id, balance, created_at FROM wallets WHERE id = $1`, wid,
+	).Scan(&w.ID, &w.OwnerID, &w.AssetTypeID, &w.Balance, &w.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (s *Store) GetTreasuryWalletByAssetTypeID(ctx context.Context, assetTypeID int) (*models.Wallet, error) {
+	var w models.Wallet
+	err := s.Pool.QueryRow(ctx, `
+		SELECT id, owner_id, asset_type_id, balance, created_at
+		FROM wallets WHERE owner_id = 1 AND asset_type_id = $1`, assetTypeID,
+	).Scan(&w.ID, &w.OwnerID, &w.AssetTypeID, &w.Balance, &w.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrWalletNotFound
 		}
-		return nil, fmt.Errorf("lock wallet %d: %w", snd, err)
+		return nil, err
 	}
+	return &w, nil
+}
 
-	srcBal := fstBal
-	if fromID == snd {
-		srcBal = sndBal
+// GetReservation looks up a reservation by ID regardless of its status.
+func (s *Store) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+	var res models.Reservation
+	err := s.Pool.QueryRow(ctx, `
+		SELECT id, wallet_id, amount, idempotency_key, description, status, expires_at, created_at
+		FROM reservations WHERE id = $1`, id,
+	).Scan(&res.ID, &res.WalletID, &res.Amount, &res.IdempotencyKey, &res.Desc, &res.Status, &res.ExpiresAt, &res.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
 	}
-	if srcBal < amt {
+	return &res, nil
+}
+
+// Reserve locks amt on wid for ttl, returning a PENDING reservation. It
+// checks available balance (balance minus already-reserved funds) rather
+// than raw balance, so concurrent SPEND/reserve calls cannot oversell. A
+// retry with the same iKey returns the existing reservation instead of
+// erroring on the idempotency_key unique constraint.
+func (s *Store) Reserve(
+	ctx context.Context,
+	wid int,
+	amt int64,
+	ttl time.Duration,
+	iKey string,
+	desc string,
+) (*models.Reservation, error) {
+	if amt <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if iKey == "" {
+		return nil, ErrMissingIdempotency
+	}
+
+	dup, err := s.findReservationByIKey(ctx, iKey)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency lookup: %w", err)
+	}
+	if dup != nil {
+		return dup, nil
+	}
+
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	var balance, reserved int64
+	err = tx.QueryRow(ctx, "SELECT balance, reserved_balance FROM wallets WHERE id = $1 FOR UPDATE", wid).
+		Scan(&balance, &reserved)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("lock wallet %d: %w", wid, err)
+	}
+	if balance-reserved < amt {
 		return nil, ErrInsufficientBalance
 	}
 
+	r := models.Reservation{
+		ID:             uuid.New().String(),
+		WalletID:       wid,
+		Amount:         amt,
+		IdempotencyKey: iKey,
+		Desc:           desc,
+		Status:         "PENDING",
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO reservations (id, wallet_id, amount, idempotency_key, description, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		r.ID, r.WalletID, r.Amount, r.IdempotencyKey, r.Desc, r.Status, r.ExpiresAt,
+	).Scan(&r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert reservation: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE wallets SET reserved_balance = reserved_balance + $1 WHERE id = $2", amt, wid); err != nil {
+		return nil, fmt.Errorf("update reserved_balance: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return &r, nil
+}
+
+// CommitReservation converts a PENDING reservation into a real DEBIT/CREDIT
+// pair against toID and releases the hold, all in one transaction. The
+// reservation's wallet and toID are locked in sorted-ID order before either
+// is updated, same as ExecutePostings, so two concurrent commits that
+// reference the same wallet pair in swapped roles can't deadlock.
+func (s *Store) CommitReservation(
+	ctx context.Context,
+	reservationID string,
+	toID int,
+	iKey string,
+	desc string,
+	txType string,
+) (*models.TxResp, error) {
+	if iKey == "" {
+		return nil, ErrMissingIdempotency
+	}
+
+	dup, err := s.findByIKey(ctx, iKey)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency lookup: %w", err)
+	}
+	if dup != nil {
+		return &models.TxResp{
+			TxGroupID:      dup[0].TxGroupID,
+			IdempotencyKey: iKey,
+			Status:         "duplicate",
+			Entries:        dup,
+		}, nil
+	}
+
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	var fromID int
+	var amt int64
+	var status string
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT wallet_id, amount, status, expires_at FROM reservations WHERE id = $1 FOR UPDATE`, reservationID,
+	).Scan(&fromID, &amt, &status, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("lock reservation %s: %w", reservationID, err)
+	}
+	if status != "PENDING" || time.Now().After(expiresAt) {
+		return nil, ErrReservationNotActive
+	}
+
+	walletIDs := []int{fromID, toID}
+	sort.Ints(walletIDs)
+	for _, id := range walletIDs {
+		if _, err := tx.Exec(ctx, "SELECT id FROM wallets WHERE id = $1 FOR UPDATE", id); err != nil {
+			return nil, fmt.Errorf("lock wallet %d: %w", id, err)
+		}
+	}
+
 	gid := uuid.New().String()
 
 	var deb models.LedgerEntry
@@ -121,15 +506,25 @@ func (s *Store) ExecuteTransfer(
 		return nil, fmt.Errorf("insert credit: %w", err)
 	}
 
-	_, err = tx.Exec(ctx, "UPDATE wallets SET balance = balance - $1 WHERE id = $2", amt, fromID)
-	if err != nil {
+	if _, err := tx.Exec(ctx,
+		"UPDATE wallets SET balance = balance - $1, reserved_balance = reserved_balance - $1 WHERE id = $2", amt, fromID); err != nil {
 		return nil, fmt.Errorf("update from-wallet: %w", err)
 	}
-
-	_, err = tx.Exec(ctx, "UPDATE wallets SET balance = balance + $1 WHERE id = $2", amt, toID)
-	if err != nil {
+	if _, err := tx.Exec(ctx, "UPDATE wallets SET balance = balance + $1 WHERE id = $2", amt, toID); err != nil {
 		return nil, fmt.Errorf("update to-wallet: %w", err)
 	}
+	if _, err := tx.Exec(ctx, "UPDATE reservations SET status = 'COMMITTED' WHERE id = $1", reservationID); err != nil {
+		return nil, fmt.Errorf("update reservation: %w", err)
+	}
+
+	if err := events.EnqueueTx(ctx, tx, events.TransferCommitted{
+		TxGroupID:   gid,
+		TxType:      txType,
+		Entries:     []models.LedgerEntry{deb, cred},
+		CommittedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("enqueue outbox event: %w", err)
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
@@ -143,60 +538,292 @@ func (s *Store) ExecuteTransfer(
 	}, nil
 }
 
-func (s *Store) GetWalletBalance(ctx context.Context, wid int) (*models.BalResp, error) {
-	var r models.BalResp
-	err := s.Pool.QueryRow(ctx, `
-		SELECT w.id, w.owner_id, a.code, w.balance
-		FROM wallets w JOIN asset_types a ON a.id = w.asset_type_id
-		WHERE w.id = $1`, wid,
-	).Scan(&r.WalletID, &r.OwnerID, &r.AssetCode, &r.Balance)
+// CancelReservation releases a PENDING reservation's hold without moving
+// any funds.
+func (s *Store) CancelReservation(ctx context.Context, reservationID string) error {
+	return s.releaseReservation(ctx, reservationID, "CANCELLED")
+}
+
+// SweepExpiredReservations releases every PENDING reservation whose TTL has
+// elapsed, returning how many were swept. Intended to be called
+// periodically by a background worker.
+func (s *Store) SweepExpiredReservations(ctx context.Context) (int, error) {
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, wallet_id, amount FROM reservations
+		WHERE status = 'PENDING' AND expires_at <= $1 FOR UPDATE`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("select expired reservations: %w", err)
+	}
+	type expired struct {
+		id  string
+		wid int
+		amt int64
+	}
+	var batch []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.id, &e.wid, &e.amt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan expired reservation: %w", err)
+		}
+		batch = append(batch, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, e := range batch {
+		if _, err := tx.Exec(ctx, "UPDATE reservations SET status = 'EXPIRED' WHERE id = $1", e.id); err != nil {
+			return 0, fmt.Errorf("expire reservation %s: %w", e.id, err)
+		}
+		if _, err := tx.Exec(ctx, "UPDATE wallets SET reserved_balance = reserved_balance - $1 WHERE id = $2", e.amt, e.wid); err != nil {
+			return 0, fmt.Errorf("release wallet %d: %w", e.wid, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return len(batch), nil
+}
+
+func (s *Store) releaseReservation(ctx context.Context, reservationID, newStatus string) error {
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	var wid int
+	var amt int64
+	var status string
+	err = tx.QueryRow(ctx, "SELECT wallet_id, amount, status FROM reservations WHERE id = $1 FOR UPDATE", reservationID).
+		Scan(&wid, &amt, &status)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrWalletNotFound
+			return ErrReservationNotFound
 		}
+		return fmt.Errorf("lock reservation %s: %w", reservationID, err)
+	}
+	if status != "PENDING" {
+		return ErrReservationNotActive
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE reservations SET status = $1 WHERE id = $2", newStatus, reservationID); err != nil {
+		return fmt.Errorf("update reservation: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE wallets SET reserved_balance = reserved_balance - $1 WHERE id = $2", amt, wid); err != nil {
+		return fmt.Errorf("release wallet %d: %w", wid, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateWebhookSubscription registers a downstream sink to receive
+// TransferCommitted events.
+func (s *Store) CreateWebhookSubscription(ctx context.Context, url, secret string) (*models.WebhookSubscription, error) {
+	sub := models.WebhookSubscription{
+		ID:     uuid.New().String(),
+		URL:    url,
+		Secret: secret,
+		Active: true,
+	}
+	err := s.Pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (id, url, secret)
+		VALUES ($1, $2, $3)
+		RETURNING created_at`,
+		sub.ID, sub.URL, sub.Secret,
+	).Scan(&sub.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// ListWebhookSubscriptions returns every registered webhook subscription,
+// including inactive ones. Secret is never populated: like an AccessToken,
+// it is only ever returned once, at creation time.
+func (s *Store) ListWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT id, url, active, created_at FROM webhook_subscriptions ORDER BY created_at`)
+	if err != nil {
 		return nil, err
 	}
-	return &r, nil
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
 }
 
-func (s *Store) GetLedgerEntries(ctx context.Context, wid int) ([]models.LedgerEntry, error) {
+// DeleteWebhookSubscription removes a webhook subscription by ID.
+func (s *Store) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	tag, err := s.Pool.Exec(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ActiveWebhookSubscriptions returns every subscription with active = true,
+// for the outbox worker to fan TransferCommitted events out to.
+func (s *Store) ActiveWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
 	rows, err := s.Pool.Query(ctx, `
-		SELECT id, tx_group_id, idempotency_key, wallet_id, entry_type, amount, tx_type, description, created_at
-		FROM ledger_entries WHERE wallet_id = $1 ORDER BY created_at DESC`, wid)
+		SELECT id, url, secret, active, created_at FROM webhook_subscriptions WHERE active`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var entries []models.LedgerEntry
+	var subs []models.WebhookSubscription
 	for rows.Next() {
-		var e models.LedgerEntry
-		if err := rows.Scan(&e.ID, &e.TxGroupID, &e.IdempotencyKey, &e.WalletID, &e.EntryType, &e.Amount, &e.TxType, &e.Desc, &e.CreatedAt); err != nil {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &sub.Active, &sub.CreatedAt); err != nil {
 			return nil, err
 		}
-		entries = append(entries, e)
+		subs = append(subs, sub)
 	}
-	return entries, rows.Err()
+	return subs, rows.Err()
 }
 
-func (s *Store) GetWalletByOwnerAndAsset(ctx context.Context, ownerID int, asset string) (*models.Wallet, error) {
-	var w models.Wallet
+// EventSubscriptions adapts Store to events.SubscriptionSource, so the
+// outbox worker can look up active webhook subscriptions without events
+// importing db (which would cycle back through events itself).
+func (s *Store) EventSubscriptions() events.SubscriptionSource {
+	return eventSubscriptionSource{s}
+}
+
+type eventSubscriptionSource struct{ s *Store }
+
+func (e eventSubscriptionSource) ActiveWebhookSubscriptions(ctx context.Context) ([]events.WebhookSubscription, error) {
+	subs, err := e.s.ActiveWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]events.WebhookSubscription, len(subs))
+	for i, sub := range subs {
+		out[i] = events.WebhookSubscription{ID: sub.ID, URL: sub.URL, Secret: sub.Secret}
+	}
+	return out, nil
+}
+
+// CreateAccessToken stores a new access token identified by tokenHash
+// (the caller generates and hands back the raw secret; only its hash is
+// persisted). userID is ignored for "admin" tokens.
+func (s *Store) CreateAccessToken(ctx context.Context, tokenType string, userID int, tokenHash string) (*models.AccessToken, error) {
+	t := models.AccessToken{ID: uuid.New().String(), Type: tokenType}
+
+	var uid *int
+	if tokenType == "client" {
+		uid = &userID
+		t.UserID = userID
+	}
 	err := s.Pool.QueryRow(ctx, `
-		SELECT w.id, w.owner_id, w.asset_type_id, w.balance, w.created_at
-		FROM wallets w JOIN asset_types a ON a.id = w.asset_type_id
-		WHERE w.owner_id = $1 AND a.code = $2`, ownerID, asset,
-	).Scan(&w.ID, &w.OwnerID, &w.AssetTypeID, &w.Balance, &w.CreatedAt)
+		INSERT INTO access_tokens (id, token_hash, type, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`,
+		t.ID, tokenHash, t.Type, uid,
+	).Scan(&t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert access token: %w", err)
+	}
+	return &t, nil
+}
+
+// GetAccessTokenByHash looks up a token by the hash of its raw secret, as
+// presented in an Authorization: Bearer header.
+func (s *Store) GetAccessTokenByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error) {
+	var t models.AccessToken
+	var uid *int
+	err := s.Pool.QueryRow(ctx, `
+		SELECT id, type, user_id, created_at FROM access_tokens WHERE token_hash = $1`, tokenHash,
+	).Scan(&t.ID, &t.Type, &uid, &t.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrWalletNotFound
+			return nil, ErrTokenNotFound
 		}
 		return nil, err
 	}
-	return &w, nil
+	if uid != nil {
+		t.UserID = *uid
+	}
+	return &t, nil
 }
 
-func (s *Store) GetTreasuryWallet(ctx context.Context, asset string) (*models.Wallet, error) {
-	return s.GetWalletByOwnerAndAsset(ctx, 1, asset)
+// ListAccessTokens returns every issued access token (never their secrets,
+// which are not stored).
+func (s *Store) ListAccessTokens(ctx context.Context) ([]models.AccessToken, error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT id, type, user_id, created_at FROM access_tokens ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.AccessToken
+	for rows.Next() {
+		var t models.AccessToken
+		var uid *int
+		if err := rows.Scan(&t.ID, &t.Type, &uid, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if uid != nil {
+			t.UserID = *uid
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteAccessToken revokes a token by ID.
+func (s *Store) DeleteAccessToken(ctx context.Context, id string) error {
+	tag, err := s.Pool.Exec(ctx, "DELETE FROM access_tokens WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("delete access token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *Store) findReservationByIKey(ctx context.Context, key string) (*models.Reservation, error) {
+	var res models.Reservation
+	err := s.Pool.QueryRow(ctx, `
+		SELECT id, wallet_id, amount, idempotency_key, description, status, expires_at, created_at
+		FROM reservations WHERE idempotency_key = $1`, key,
+	).Scan(&res.ID, &res.WalletID, &res.Amount, &res.IdempotencyKey, &res.Desc, &res.Status, &res.ExpiresAt, &res.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &res, nil
 }
 
 func (s *Store) findByIKey(ctx context.Context, key string) ([]models.LedgerEntry, error) {
@@ -0,0 +1,203 @@
+// Package migrations applies numbered, forward-only schema migrations and
+// tracks the applied version in a schema_version table. internal/db.NewStore
+// refuses to start when the code's expected version and the database's
+// recorded version disagree, so a running binary is never pointed at a
+// schema it doesn't understand.
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migration is one forward-only, numbered schema change. Versions must be
+// contiguous starting at 1 and are applied in order.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// All holds every migration in version order. Append new migrations to the
+// end; never edit or remove an already-shipped one.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "init_schema",
+		SQL: `
+CREATE TABLE IF NOT EXISTS asset_types (
+	id   SERIAL PRIMARY KEY,
+	code TEXT NOT NULL UNIQUE,
+	name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	id         SERIAL PRIMARY KEY,
+	username   TEXT NOT NULL UNIQUE,
+	user_type  TEXT NOT NULL DEFAULT 'standard',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS wallets (
+	id            SERIAL PRIMARY KEY,
+	owner_id      INTEGER NOT NULL,
+	asset_type_id INTEGER NOT NULL REFERENCES asset_types (id),
+	balance       BIGINT NOT NULL DEFAULT 0,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (owner_id, asset_type_id)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+	id              BIGSERIAL PRIMARY KEY,
+	tx_group_id     TEXT NOT NULL,
+	idempotency_key TEXT NOT NULL,
+	wallet_id       INTEGER NOT NULL REFERENCES wallets (id),
+	entry_type      TEXT NOT NULL CHECK (entry_type IN ('DEBIT', 'CREDIT')),
+	amount          BIGINT NOT NULL CHECK (amount > 0),
+	tx_type         TEXT NOT NULL,
+	description     TEXT NOT NULL DEFAULT '',
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_wallet_id ON ledger_entries (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_idempotency_key ON ledger_entries (idempotency_key);
+`,
+	},
+	{
+		Version: 2,
+		Name:    "reservations",
+		SQL: `
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS reserved_balance BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reservations (
+	id              TEXT PRIMARY KEY,
+	wallet_id       INTEGER NOT NULL REFERENCES wallets (id),
+	amount          BIGINT NOT NULL CHECK (amount > 0),
+	idempotency_key TEXT NOT NULL UNIQUE,
+	description     TEXT NOT NULL DEFAULT '',
+	status          TEXT NOT NULL CHECK (status IN ('PENDING', 'COMMITTED', 'CANCELLED', 'EXPIRED')),
+	expires_at      TIMESTAMPTZ NOT NULL,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_status_expires_at ON reservations (status, expires_at);
+`,
+	},
+	{
+		Version: 3,
+		Name:    "events",
+		SQL: `
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	id         TEXT PRIMARY KEY,
+	url        TEXT NOT NULL,
+	secret     TEXT NOT NULL,
+	active     BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+	id           BIGSERIAL PRIMARY KEY,
+	event_type   TEXT NOT NULL,
+	payload      JSONB NOT NULL,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	delivered_at TIMESTAMPTZ,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undelivered ON outbox_events (id) WHERE delivered_at IS NULL;
+`,
+	},
+	{
+		Version: 4,
+		Name:    "access_tokens",
+		SQL: `
+CREATE TABLE IF NOT EXISTS access_tokens (
+	id         TEXT PRIMARY KEY,
+	token_hash TEXT NOT NULL UNIQUE,
+	type       TEXT NOT NULL CHECK (type IN ('admin', 'client')),
+	user_id    INTEGER,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`,
+	},
+}
+
+// CurrentVersion is the schema version this build of the code expects,
+// i.e. the highest version number in All.
+func CurrentVersion() int {
+	v := 0
+	for _, m := range All {
+		if m.Version > v {
+			v = m.Version
+		}
+	}
+	return v
+}
+
+// AppliedVersion returns the version recorded in schema_version, or 0 if
+// the table does not exist yet (a brand-new database).
+func AppliedVersion(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	var exists bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (
+		SELECT 1 FROM information_schema.tables WHERE table_name = 'schema_version'
+	)`).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("check schema_version table: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	err = pool.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("read schema_version: %w", err)
+	}
+	return version, nil
+}
+
+// Apply runs every migration whose version is greater than the database's
+// currently applied version, in order, each in its own transaction, and
+// records it in schema_version. It is idempotent: running it again is a
+// no-op once the database is up to date.
+func Apply(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version    INTEGER PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return fmt.Errorf("create schema_version: %w", err)
+	}
+
+	applied, err := AppliedVersion(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range All {
+		if m.Version <= applied {
+			continue
+		}
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", m.Version, err)
+		}
+
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_version (version) VALUES ($1)`, m.Version); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("record migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("commit migration %d: %w", m.Version, err)
+		}
+	}
+
+	return nil
+}
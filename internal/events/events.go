@@ -0,0 +1,31 @@
+// Package events defines the ledger's event bus: a TransferCommitted event
+// emitted after every committed transfer, persisted to an outbox in the
+// same DB transaction as the ledger write, and a Publisher interface for
+// delivering it to downstream sinks (HTTP webhooks, Kafka) with
+// at-least-once semantics.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/inodinwetrust10/rewardPoints/internal/models"
+)
+
+// TransferCommitted describes a completed transfer for downstream
+// consumers (fraud, notifications, analytics) to react to without polling
+// GetLedgerEntries.
+type TransferCommitted struct {
+	TxGroupID   string               `json:"tx_group_id"`
+	TxType      string               `json:"tx_type"`
+	Entries     []models.LedgerEntry `json:"entries"`
+	CommittedAt time.Time            `json:"committed_at"`
+}
+
+// Publisher delivers a TransferCommitted event to one downstream sink.
+// Implementations (webhook, Kafka) are expected to be at-least-once:
+// the outbox worker retries on error, so Publish must be safe to call more
+// than once for the same event.
+type Publisher interface {
+	Publish(ctx context.Context, event TransferCommitted) error
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes the event as a single JSON message carrying all
+// entries of the transfer, keyed by TxGroupID. Transfers touching the same
+// wallet have different keys and may land on different partitions, so
+// per-wallet ordering is not preserved across transfers.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher builds a publisher for the given brokers and topic.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event TransferCommitted) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.TxGroupID),
+		Value: value,
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
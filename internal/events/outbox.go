@@ -0,0 +1,195 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnqueueTx persists event into outbox_events using tx, so the write lands
+// in the same transaction as the ledger entries it describes. It is called
+// from inside db.Store.ExecutePostings and db.Store.CommitReservation
+// before committing.
+func EnqueueTx(ctx context.Context, tx pgx.Tx, event TransferCommitted) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO outbox_events (event_type, payload) VALUES ('TransferCommitted', $1)`, payload)
+	if err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// outboxRow is one undelivered outbox_events row.
+type outboxRow struct {
+	ID       int64
+	Payload  TransferCommitted
+	Attempts int
+}
+
+// highOutboxAttempts is logged as a warning once a row's attempts count
+// crosses it, so a stuck subscriber shows up in logs well before anyone
+// needs to look at outbox_events directly. It does not stop the row from
+// being claimed; claim relies solely on delivered_at IS NULL, so delivery
+// is retried every poll until it succeeds.
+const highOutboxAttempts = 5
+
+// WebhookSubscription is the subset of a registered subscription the
+// worker needs to fan an event out to it.
+type WebhookSubscription struct {
+	ID     string
+	URL    string
+	Secret string
+}
+
+// SubscriptionSource looks up the webhook subscriptions currently active,
+// so the worker can fan out to subscriptions registered after it started
+// without a restart.
+type SubscriptionSource interface {
+	ActiveWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+}
+
+// Worker polls outbox_events for undelivered rows and fans each one out to
+// every configured Publisher plus a WebhookPublisher per active
+// subscription, marking the row delivered once all of them succeed. It is
+// safe to run on multiple processes: rows are claimed with FOR UPDATE SKIP
+// LOCKED.
+type Worker struct {
+	Pool          *pgxpool.Pool
+	Publishers    []Publisher
+	Subscriptions SubscriptionSource
+	Interval      time.Duration
+}
+
+// NewWorker builds a Worker with the repo-default poll interval. publishers
+// are delivered to on every event (e.g. Kafka/NATS sinks); webhook
+// subscriptions are looked up per batch via subs.
+func NewWorker(pool *pgxpool.Pool, subs SubscriptionSource, publishers ...Publisher) *Worker {
+	return &Worker{Pool: pool, Publishers: publishers, Subscriptions: subs, Interval: 2 * time.Second}
+}
+
+// Run polls and delivers until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.deliverBatch(ctx); err != nil {
+				log.Printf("outbox worker: %v", err)
+			}
+		}
+	}
+}
+
+func (w *Worker) deliverBatch(ctx context.Context) error {
+	rows, err := w.claim(ctx, 50)
+	if err != nil {
+		return fmt.Errorf("claim outbox rows: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	pubs := append([]Publisher{}, w.Publishers...)
+	if w.Subscriptions != nil {
+		subs, err := w.Subscriptions.ActiveWebhookSubscriptions(ctx)
+		if err != nil {
+			return fmt.Errorf("list webhook subscriptions: %w", err)
+		}
+		for _, sub := range subs {
+			pubs = append(pubs, NewWebhookPublisher(sub.URL, sub.Secret))
+		}
+	}
+
+	for _, row := range rows {
+		var failed error
+		for _, pub := range pubs {
+			if err := pub.Publish(ctx, row.Payload); err != nil {
+				failed = err
+				break
+			}
+		}
+		if failed != nil {
+			if row.Attempts >= highOutboxAttempts {
+				log.Printf("outbox event %d: delivery still failing after %d attempts: %v", row.ID, row.Attempts, failed)
+			} else {
+				log.Printf("outbox event %d: delivery failed (attempt %d): %v", row.ID, row.Attempts, failed)
+			}
+			continue
+		}
+		if _, err := w.Pool.Exec(ctx, `UPDATE outbox_events SET delivered_at = now() WHERE id = $1`, row.ID); err != nil {
+			log.Printf("outbox event %d: mark delivered: %v", row.ID, err)
+		}
+	}
+	return nil
+}
+
+// claim locks up to limit undelivered rows and bumps their attempts count
+// within one short transaction, so concurrent claims never return the same
+// row; the (possibly slow) delivery itself happens after commit. The lock
+// ends at commit and delivered_at is only set after delivery, so another
+// worker's poll can claim a row again while it is still being delivered.
+func (w *Worker) claim(ctx context.Context, limit int) ([]outboxRow, error) {
+	tx, err := w.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin claim tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, payload, attempts FROM outbox_events
+		WHERE delivered_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []outboxRow
+	for rows.Next() {
+		var r outboxRow
+		var payload []byte
+		if err := rows.Scan(&r.ID, &payload, &r.Attempts); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &r.Payload); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("unmarshal outbox event %d: %w", r.ID, err)
+		}
+		r.Attempts++
+		out = append(out, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range out {
+		if _, err := tx.Exec(ctx, `UPDATE outbox_events SET attempts = $1 WHERE id = $2`, r.Attempts, r.ID); err != nil {
+			return nil, fmt.Errorf("bump attempts for event %d: %w", r.ID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit claim tx: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookPublisher POSTs the event as JSON to a single subscriber URL,
+// signing the body with HMAC-SHA256 over a per-subscription secret so the
+// receiver can verify authenticity, and retrying with exponential backoff
+// on failure.
+type WebhookPublisher struct {
+	URL        string
+	Secret     string
+	Client     *http.Client
+	MaxRetries int
+}
+
+// NewWebhookPublisher builds a WebhookPublisher with repo-default timeouts
+// and retry count.
+func NewWebhookPublisher(url, secret string) *WebhookPublisher {
+	return &WebhookPublisher{
+		URL:        url,
+		Secret:     secret,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		MaxRetries: 5,
+	}
+}
+
+// SignatureHeader is the HTTP header carrying the hex-encoded HMAC-SHA256
+// signature of the request body.
+const SignatureHeader = "X-Webhook-Signature"
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event TransferCommitted) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	sig := sign(p.Secret, body)
+
+	var lastErr error
+	for attempt := 0; attempt <= p.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, sig)
+
+		resp, err := p.Client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook %s: unexpected status %d", p.URL, resp.StatusCode)
+	}
+	return fmt.Errorf("webhook %s: exhausted retries: %w", p.URL, lastErr)
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns an exponential delay for the given attempt, capped at 30s.
+func backoff(attempt int) time.Duration {
+	d := time.Duration(1<<uint(attempt)) * time.Second
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
@@ -0,0 +1,4 @@
+// Package grpc contains no server code. The repository holds no .proto
+// definition of a wallet service and no generated protobuf/gRPC bindings,
+// so the wallet operations are served only by the chi HTTP API.
+package grpc
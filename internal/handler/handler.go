@@ -6,13 +6,18 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/inodinwetrust10/rewardPoints/internal/auth"
 	"github.com/inodinwetrust10/rewardPoints/internal/db"
 	"github.com/inodinwetrust10/rewardPoints/internal/models"
 )
 
+// defaultReservationTTL is used when a reserve request omits ttl_seconds.
+const defaultReservationTTL = 5 * time.Minute
+
 type Handler struct {
 	s *db.Store
 }
@@ -21,13 +26,37 @@ func New(s *db.Store) *Handler {
 	return &Handler{s: s}
 }
 
-func (h *Handler) RegisterRoutes(r chi.Router) {
+// RegisterRoutes mounts /api/v1 on r, behind a.Middleware so every route
+// requires a valid bearer token. Bonus, postings, webhook management, and
+// token management are further restricted to admin tokens; Spend is open to
+// client tokens but enforces the token's own user_id in the handler.
+func (h *Handler) RegisterRoutes(r chi.Router, a *auth.Authenticator) {
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(a.Middleware)
+
 		r.Post("/topup", h.TopUp)
-		r.Post("/bonus", h.Bonus)
+		r.With(auth.RequireScopes(auth.TokenAdmin)).Post("/bonus", h.Bonus)
 		r.Post("/spend", h.Spend)
+		r.With(auth.RequireScopes(auth.TokenAdmin)).Post("/postings", h.Postings)
+		r.Post("/reserve", h.Reserve)
+		r.Post("/spend/commit", h.CommitSpend)
+		r.Post("/spend/cancel", h.CancelSpend)
 		r.Get("/wallets/{walletId}/balance", h.GetBalance)
 		r.Get("/wallets/{walletId}/ledger", h.GetLedger)
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(auth.RequireScopes(auth.TokenAdmin))
+			r.Post("/", h.CreateWebhook)
+			r.Get("/", h.ListWebhooks)
+			r.Delete("/{webhookId}", h.DeleteWebhook)
+		})
+
+		r.Route("/tokens", func(r chi.Router) {
+			r.Use(auth.RequireScopes(auth.TokenAdmin))
+			r.Post("/", h.CreateToken)
+			r.Get("/", h.ListTokens)
+			r.Delete("/{tokenId}", h.DeleteToken)
+		})
 	})
 }
 
@@ -43,6 +72,202 @@ func (h *Handler) Spend(w http.ResponseWriter, r *http.Request) {
 	h.transfer(w, r, "SPEND", true)
 }
 
+// Reserve locks points against a user's wallet for a TTL, quoting a cost
+// that is only finalized once CommitSpend is called.
+func (h *Handler) Reserve(w http.ResponseWriter, r *http.Request) {
+	var req models.ReserveReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.IdempotencyKey == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		return
+	}
+	if req.UserID == 0 {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "user_id is required"})
+		return
+	}
+	if req.AssetCode == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "asset_code is required"})
+		return
+	}
+	if req.Amount <= 0 {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "amount must be positive"})
+		return
+	}
+	if t, ok := auth.FromContext(r.Context()); ok && t.Type == auth.TokenClient && t.UserID != req.UserID {
+		js(w, http.StatusForbidden, models.ErrResp{Error: "token is not permitted to act for this user"})
+		return
+	}
+
+	ttl := defaultReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	ctx := r.Context()
+	uw, err := h.s.GetWalletByOwnerAndAsset(ctx, req.UserID, req.AssetCode)
+	if err != nil {
+		if errors.Is(err, db.ErrWalletNotFound) {
+			js(w, http.StatusNotFound, models.ErrResp{Error: "user wallet not found"})
+			return
+		}
+		log.Printf("resolve user wallet: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+
+	desc := req.Desc
+	if desc == "" {
+		desc = "SPEND reservation"
+	}
+
+	res, err := h.s.Reserve(ctx, uw.ID, req.Amount, ttl, req.IdempotencyKey, desc)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "insufficient balance"})
+		case errors.Is(err, db.ErrInvalidAmount):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "amount must be positive"})
+		case errors.Is(err, db.ErrMissingIdempotency):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		default:
+			log.Printf("Reserve: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		}
+		return
+	}
+	js(w, http.StatusCreated, res)
+}
+
+// CommitSpend converts a reservation into a real DEBIT/CREDIT pair against
+// the treasury wallet for the reservation's asset.
+func (h *Handler) CommitSpend(w http.ResponseWriter, r *http.Request) {
+	var req models.CommitReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.ReservationID == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "reservation_id is required"})
+		return
+	}
+	if req.IdempotencyKey == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		return
+	}
+
+	ctx := r.Context()
+	// The reservation already pins the source wallet and asset; the spend
+	// always settles against that asset's treasury wallet, mirroring Spend.
+	res, err := h.s.GetReservation(ctx, req.ReservationID)
+	if err != nil {
+		if errors.Is(err, db.ErrReservationNotFound) {
+			js(w, http.StatusNotFound, models.ErrResp{Error: "reservation not found"})
+			return
+		}
+		log.Printf("resolve reservation: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	uw, err := h.s.GetWalletByID(ctx, res.WalletID)
+	if err != nil {
+		log.Printf("resolve reserved wallet: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	if t, ok := auth.FromContext(ctx); ok && t.Type == auth.TokenClient && t.UserID != uw.OwnerID {
+		js(w, http.StatusForbidden, models.ErrResp{Error: "token is not permitted to act for this user"})
+		return
+	}
+	tw, err := h.s.GetTreasuryWalletByAssetTypeID(ctx, uw.AssetTypeID)
+	if err != nil {
+		log.Printf("resolve treasury wallet: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+
+	desc := req.Desc
+	if desc == "" {
+		desc = "SPEND transaction"
+	}
+
+	resp, err := h.s.CommitReservation(ctx, req.ReservationID, tw.ID, req.IdempotencyKey, desc, "SPEND")
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrReservationNotFound):
+			js(w, http.StatusNotFound, models.ErrResp{Error: "reservation not found"})
+		case errors.Is(err, db.ErrReservationNotActive):
+			js(w, http.StatusConflict, models.ErrResp{Error: "reservation is not pending"})
+		case errors.Is(err, db.ErrMissingIdempotency):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		default:
+			log.Printf("CommitReservation: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		}
+		return
+	}
+
+	code := http.StatusCreated
+	if resp.Status == "duplicate" {
+		code = http.StatusOK
+	}
+	js(w, code, resp)
+}
+
+// CancelSpend releases a PENDING reservation's hold without moving funds.
+func (h *Handler) CancelSpend(w http.ResponseWriter, r *http.Request) {
+	var req models.CancelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.ReservationID == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "reservation_id is required"})
+		return
+	}
+
+	ctx := r.Context()
+	if t, ok := auth.FromContext(ctx); ok && t.Type == auth.TokenClient {
+		res, err := h.s.GetReservation(ctx, req.ReservationID)
+		if err != nil {
+			if errors.Is(err, db.ErrReservationNotFound) {
+				js(w, http.StatusNotFound, models.ErrResp{Error: "reservation not found"})
+				return
+			}
+			log.Printf("resolve reservation: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+			return
+		}
+		uw, err := h.s.GetWalletByID(ctx, res.WalletID)
+		if err != nil {
+			log.Printf("resolve reserved wallet: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+			return
+		}
+		if t.UserID != uw.OwnerID {
+			js(w, http.StatusForbidden, models.ErrResp{Error: "token is not permitted to act for this user"})
+			return
+		}
+	}
+
+	err := h.s.CancelReservation(ctx, req.ReservationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrReservationNotFound):
+			js(w, http.StatusNotFound, models.ErrResp{Error: "reservation not found"})
+		case errors.Is(err, db.ErrReservationNotActive):
+			js(w, http.StatusConflict, models.ErrResp{Error: "reservation is not pending"})
+		default:
+			log.Printf("CancelReservation: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	wid, err := strconv.Atoi(chi.URLParam(r, "walletId"))
 	if err != nil {
@@ -80,6 +305,108 @@ func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request) {
 	js(w, http.StatusOK, entries)
 }
 
+// CreateWebhook registers a downstream sink for TransferCommitted events.
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.URL == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "url is required"})
+		return
+	}
+	if req.Secret == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "secret is required"})
+		return
+	}
+
+	sub, err := h.s.CreateWebhookSubscription(r.Context(), req.URL, req.Secret)
+	if err != nil {
+		log.Printf("CreateWebhookSubscription: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	js(w, http.StatusCreated, sub)
+}
+
+// ListWebhooks lists every registered webhook subscription.
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.s.ListWebhookSubscriptions(r.Context())
+	if err != nil {
+		log.Printf("ListWebhookSubscriptions: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+	js(w, http.StatusOK, subs)
+}
+
+// DeleteWebhook removes a webhook subscription by ID.
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "webhookId")
+	if err := h.s.DeleteWebhookSubscription(r.Context(), id); err != nil {
+		if errors.Is(err, db.ErrWebhookNotFound) {
+			js(w, http.StatusNotFound, models.ErrResp{Error: "webhook subscription not found"})
+			return
+		}
+		log.Printf("DeleteWebhookSubscription: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Postings applies an arbitrary balanced set of debits and credits under a
+// single tx_group_id, for patterns the 2-entry transfer handlers can't
+// express: a spend split between a merchant and a platform fee, a top-up
+// paired with a promo bonus, or a payout split across treasuries.
+func (h *Handler) Postings(w http.ResponseWriter, r *http.Request) {
+	var req models.PostingsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.IdempotencyKey == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		return
+	}
+	if req.TxType == "" {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "tx_type is required"})
+		return
+	}
+
+	resp, err := h.s.ExecutePostings(r.Context(), req.Postings, req.IdempotencyKey, req.Desc, req.TxType)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "insufficient balance"})
+		case errors.Is(err, db.ErrWalletNotFound):
+			js(w, http.StatusNotFound, models.ErrResp{Error: "wallet not found"})
+		case errors.Is(err, db.ErrInvalidAmount):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "each posting amount must be non-zero"})
+		case errors.Is(err, db.ErrInvalidPostings):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "at least two postings are required"})
+		case errors.Is(err, db.ErrUnbalancedPostings):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "postings must sum to zero"})
+		case errors.Is(err, db.ErrMissingIdempotency):
+			js(w, http.StatusBadRequest, models.ErrResp{Error: "idempotency_key is required"})
+		default:
+			log.Printf("ExecutePostings: %v", err)
+			js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		}
+		return
+	}
+
+	code := http.StatusCreated
+	if resp.Status == "duplicate" {
+		code = http.StatusOK
+	}
+	js(w, code, resp)
+}
+
 func (h *Handler) transfer(w http.ResponseWriter, r *http.Request, txType string, userIsSrc bool) {
 	var req models.TxReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -103,6 +430,15 @@ func (h *Handler) transfer(w http.ResponseWriter, r *http.Request, txType string
 		return
 	}
 
+	// Bonus is admin-only at the route level; Spend is open to both token
+	// types, but a client token may only Spend on its own behalf.
+	if t, ok := auth.FromContext(r.Context()); ok && t.Type == auth.TokenClient {
+		if txType != "SPEND" || t.UserID != req.UserID {
+			js(w, http.StatusForbidden, models.ErrResp{Error: "token is not permitted to act for this user"})
+			return
+		}
+	}
+
 	ctx := r.Context()
 
 	uw, err := h.s.GetWalletByOwnerAndAsset(ctx, req.UserID, req.AssetCode)
@@ -162,6 +498,68 @@ func (h *Handler) transfer(w http.ResponseWriter, r *http.Request, txType string
 	js(w, code, resp)
 }
 
+// CreateToken mints a new access token. The raw token is returned only in
+// this response; it is never stored or retrievable again.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTokenReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "invalid JSON body"})
+		return
+	}
+	if req.Type != auth.TokenAdmin && req.Type != auth.TokenClient {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "type must be 'admin' or 'client'"})
+		return
+	}
+	if req.Type == auth.TokenClient && req.UserID == 0 {
+		js(w, http.StatusBadRequest, models.ErrResp{Error: "user_id is required for client tokens"})
+		return
+	}
+
+	raw, hash, err := auth.GenerateToken()
+	if err != nil {
+		log.Printf("GenerateToken: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	t, err := h.s.CreateAccessToken(r.Context(), req.Type, req.UserID, hash)
+	if err != nil {
+		log.Printf("CreateAccessToken: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	js(w, http.StatusCreated, models.CreateTokenResp{AccessToken: *t, Token: raw})
+}
+
+// ListTokens lists every issued access token. Raw secrets are never
+// returned, as they are not stored.
+func (h *Handler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.s.ListAccessTokens(r.Context())
+	if err != nil {
+		log.Printf("ListAccessTokens: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	if tokens == nil {
+		tokens = []models.AccessToken{}
+	}
+	js(w, http.StatusOK, tokens)
+}
+
+// DeleteToken revokes an access token by ID.
+func (h *Handler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "tokenId")
+	if err := h.s.DeleteAccessToken(r.Context(), id); err != nil {
+		if errors.Is(err, db.ErrTokenNotFound) {
+			js(w, http.StatusNotFound, models.ErrResp{Error: "access token not found"})
+			return
+		}
+		log.Printf("DeleteAccessToken: %v", err)
+		js(w, http.StatusInternalServerError, models.ErrResp{Error: "internal error"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func js(w http.ResponseWriter, code int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -50,13 +50,99 @@ type TxResp struct {
 	Entries        []LedgerEntry `json:"entries"`
 }
 
+// Posting is one leg of a multi-entry transfer: a debit (negative Amount)
+// or credit (positive Amount) against WalletID. A balanced set of
+// Postings sums to zero.
+type Posting struct {
+	WalletID int   `json:"wallet_id"`
+	Amount   int64 `json:"amount"`
+}
+
+type PostingsReq struct {
+	IdempotencyKey string    `json:"idempotency_key"`
+	Postings       []Posting `json:"postings"`
+	TxType         string    `json:"tx_type"`
+	Desc           string    `json:"description"`
+}
+
 type BalResp struct {
-	WalletID  int    `json:"wallet_id"`
-	OwnerID   int    `json:"owner_id"`
-	AssetCode string `json:"asset_code"`
-	Balance   int64  `json:"balance"`
+	WalletID         int    `json:"wallet_id"`
+	OwnerID          int    `json:"owner_id"`
+	AssetCode        string `json:"asset_code"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+}
+
+// Reservation holds points against a wallet for ReserveReq.TTLSeconds before
+// being committed, cancelled, or swept once expired.
+type Reservation struct {
+	ID             string    `json:"reservation_id"`
+	WalletID       int       `json:"wallet_id"`
+	Amount         int64     `json:"amount"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Desc           string    `json:"description"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ReserveReq struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	UserID         int    `json:"user_id"`
+	AssetCode      string `json:"asset_code"`
+	Amount         int64  `json:"amount"`
+	Desc           string `json:"description"`
+	TTLSeconds     int64  `json:"ttl_seconds"`
+}
+
+type CommitReq struct {
+	ReservationID  string `json:"reservation_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Desc           string `json:"description"`
+}
+
+type CancelReq struct {
+	ReservationID string `json:"reservation_id"`
 }
 
 type ErrResp struct {
 	Error string `json:"error"`
 }
+
+// WebhookSubscription is a downstream HTTP sink registered to receive
+// TransferCommitted events, signed with Secret via HMAC-SHA256. Secret is
+// never stored back out to callers after creation; it is returned once,
+// in the response to CreateWebhook.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateWebhookReq struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// AccessToken is a bearer credential for /api/v1. Admin tokens may call
+// every operation; client tokens are scoped to a single UserID and may
+// only Spend on that user's behalf. The raw secret is never stored, only
+// its hash; it is returned once, in CreateTokenResp, at creation time.
+type AccessToken struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	UserID    int       `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateTokenReq struct {
+	Type   string `json:"type"`
+	UserID int    `json:"user_id"`
+}
+
+type CreateTokenResp struct {
+	AccessToken
+	Token string `json:"token"`
+}